@@ -0,0 +1,63 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package quorum
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the network topology",
+	Long:  `Export the network topology built by the configured builder to a file or stdout (use hyphen)`,
+	RunE:  executeExportCmd,
+}
+
+var (
+	exportFormat string
+	exportOutput string
+)
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "compose", "Export format: compose")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "-", "Write the export to a file or stdout (use hyphen)")
+	Cmd.AddCommand(exportCmd)
+}
+
+func executeExportCmd(cmd *cobra.Command, args []string) error {
+	w := os.Stdout
+	if exportOutput != "-" {
+		f, err := os.Create(exportOutput)
+		if err != nil {
+			return fmt.Errorf("export: %s", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	switch exportFormat {
+	case "compose":
+		return builder.ExportCompose(w)
+	default:
+		return fmt.Errorf("export: unsupported format %q", exportFormat)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package quorum
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jpmorganchase/quorum-tools/docker"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import [name]",
+	Short: "Import a network topology",
+	Long:  `Reconstruct a network from a docker-compose file previously produced by "export", and make it the active builder for subsequent commands`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  executeImportCmd,
+}
+
+var importSource string
+
+func init() {
+	importCmd.Flags().StringVarP(&importSource, "file", "f", "-", "Read the compose file from a path or stdin (use hyphen)")
+	Cmd.AddCommand(importCmd)
+}
+
+func executeImportCmd(cmd *cobra.Command, args []string) error {
+	r := os.Stdin
+	if importSource != "-" {
+		f, err := os.Open(importSource)
+		if err != nil {
+			return fmt.Errorf("import: %s", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	b, err := docker.NewQuorumBuilderFromCompose(r, args[0])
+	if err != nil {
+		return fmt.Errorf("import: %s", err)
+	}
+	builder = b
+	return nil
+}
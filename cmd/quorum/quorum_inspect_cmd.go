@@ -0,0 +1,77 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package quorum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/docker/docker/client"
+
+	"github.com/spf13/cobra"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/jpmorganchase/quorum-tools/docker"
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect [provision-id]",
+	Short: "Inspect a running network",
+	Long:  `Query a running network by its provisioning label and print its NetworkInfo document`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  executeInspectCmd,
+}
+
+var inspectFormat string
+
+func init() {
+	inspectCmd.Flags().StringVarP(&inspectFormat, "format", "f", "json", "Output format: json or yaml")
+	Cmd.AddCommand(inspectCmd)
+}
+
+func executeInspectCmd(cmd *cobra.Command, args []string) error {
+	dockerClient, err := client.NewEnvClient()
+	if err != nil {
+		return fmt.Errorf("inspect: %s", err)
+	}
+
+	info, err := docker.InspectByLabel(context.Background(), dockerClient, args[0], nil)
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	switch inspectFormat {
+	case "json":
+		out, err = json.MarshalIndent(info, "", "  ")
+	case "yaml":
+		out, err = yaml.Marshal(info)
+	default:
+		return fmt.Errorf("inspect: unsupported format %q", inspectFormat)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stdout, string(out))
+	return nil
+}
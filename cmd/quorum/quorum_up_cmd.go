@@ -20,6 +20,8 @@
 package quorum
 
 import (
+	"context"
+
 	"github.com/spf13/cobra"
 )
 
@@ -30,12 +32,17 @@ var upCmd = &cobra.Command{
 	RunE:  executeUpCmd,
 }
 
-var export string
+var (
+	export    string
+	waitPeers bool
+)
 
 func init() {
 	upCmd.Flags().StringVarP(&export, "export", "e", "", "Export information about the network to a file or stdout (use hyphen)")
+	upCmd.Flags().BoolVar(&waitPeers, "wait-peers", false, "Wait for full IBFT peer mesh convergence before returning")
 }
 
 func executeUpCmd(cmd *cobra.Command, args []string) error {
-	return builder.Build(export)
+	builder.ConfigureWaitPeers(waitPeers)
+	return builder.Build(context.Background(), export)
 }
@@ -21,10 +21,14 @@ package docker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 
@@ -33,12 +37,14 @@ import (
 
 	"github.com/docker/docker/client"
 
+	"github.com/hashicorp/go-multierror"
+
 	"gopkg.in/yaml.v2"
 )
 
 type Container interface {
-	Start() error
-	Stop() error
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
 }
 
 type QuorumBuilderConsensus struct {
@@ -47,6 +53,7 @@ type QuorumBuilderConsensus struct {
 }
 
 type QuorumBuilderNodeDocker struct {
+	Type   string            `yaml:"type"`
 	Image  string            `yaml:"image"`
 	Config map[string]string `yaml:"config"`
 }
@@ -62,9 +69,76 @@ type QuorumBuilder struct {
 	Consensus QuorumBuilderConsensus `yaml:"consensus"`
 	Nodes     []QuorumBuilderNode    `yaml:",flow"`
 
+	// commonLabels is the fixed set of labels (currently just the
+	// provisioning id) applied to every container/network this builder
+	// creates. It is written once, before any container is created, and
+	// only ever read afterwards, so it's safe to share across the
+	// concurrent goroutines startContainers spawns without locking.
 	commonLabels  map[string]string
 	dockerClient  *client.Client
 	dockerNetwork *Network
+
+	// imageDigests caches the resolved digest of each image pullImage has
+	// pulled, keyed by digestLabelKey(image). Unlike commonLabels it's
+	// mutated throughout Build by concurrent pullImage calls, so it's kept
+	// in its own map guarded by its own mutex rather than folded into the
+	// labels handed to container creation.
+	imageDigests   map[string]string
+	imageDigestsMu sync.Mutex
+
+	contentTrust     bool
+	maxConcurrency   int
+	readinessTimeout time.Duration
+	requireFullMesh  bool
+}
+
+// recordImageDigest remembers the resolved digest for image, reported by
+// Inspect as that image's ImageDigest.
+func (qb *QuorumBuilder) recordImageDigest(image, digest string) {
+	qb.imageDigestsMu.Lock()
+	defer qb.imageDigestsMu.Unlock()
+	if qb.imageDigests == nil {
+		qb.imageDigests = map[string]string{}
+	}
+	qb.imageDigests[digestLabelKey(image)] = digest
+}
+
+// imageDigest returns the digest pullImage resolved for image, or "" if
+// image hasn't been pulled (or pulled through) this builder yet.
+func (qb *QuorumBuilder) imageDigest(image string) string {
+	qb.imageDigestsMu.Lock()
+	defer qb.imageDigestsMu.Unlock()
+	return qb.imageDigests[digestLabelKey(image)]
+}
+
+// ConfigureWaitPeers, when set, makes startQuorums' readiness check wait for
+// admin_peers to report every other node in the network (len(Nodes)-1 peers)
+// before declaring a Quorum node ready, instead of only checking that its
+// JSON-RPC endpoint answers.
+func (qb *QuorumBuilder) ConfigureWaitPeers(wait bool) {
+	qb.requireFullMesh = wait
+}
+
+// ConfigureContentTrust, when set, makes pullImage refuse to pull any image
+// reference that isn't already digest-pinned (repo@sha256:...) or resolvable
+// to a notary-signed tag, mirroring Docker's --disable-content-trust=false.
+func (qb *QuorumBuilder) ConfigureContentTrust(trust bool) {
+	qb.contentTrust = trust
+}
+
+// ConfigureConcurrency bounds how many containers/pulls/removals are ever
+// in flight at once. It defaults to runtime.NumCPU(). One goroutine is still
+// started per node or element; each waits for one of the n slots before it
+// does any Docker work, so at most n daemon calls run together.
+func (qb *QuorumBuilder) ConfigureConcurrency(n int) {
+	qb.maxConcurrency = n
+}
+
+func (qb *QuorumBuilder) concurrency() int {
+	if qb.maxConcurrency > 0 {
+		return qb.maxConcurrency
+	}
+	return runtime.NumCPU()
 }
 
 func NewQuorumBuilder(r io.Reader) (*QuorumBuilder, error) {
@@ -89,23 +163,50 @@ func NewQuorumBuilder(r io.Reader) (*QuorumBuilder, error) {
 // 1. Build Docker Network
 // 2. Start Tx Manager
 // 3. Start Quorum
-func (qb *QuorumBuilder) Build() error {
+//
+// ctx is threaded through the image pulls and container starts Build makes
+// (creating the docker network does not take it); cancelling it (or a
+// failure partway through, which cancels an internally derived context)
+// aborts in-flight pulls and starts promptly instead of leaving them to run
+// to completion.
+func (qb *QuorumBuilder) Build(ctx context.Context, export string) error {
 	if err := qb.buildDockerNetwork(); err != nil {
 		return err
 	}
-	if err := qb.startTxManagers(); err != nil {
+	if err := qb.startTxManagers(ctx); err != nil {
+		return err
+	}
+	if err := qb.startQuorums(ctx); err != nil {
 		return err
 	}
+	if export != "" {
+		return qb.exportNetworkInfo(ctx, export)
+	}
 	return nil
 }
 
-func (qb *QuorumBuilder) startTxManagers() error {
-	log.Debug("Start Tx Managers")
-	return qb.startContainers(func(idx int, node QuorumBuilderNode) (Container, error) {
-		if err := qb.pullImage(node.TxManager.Image); err != nil {
-			return nil, err
-		}
-		return NewTesseraTxManager(
+// defaultTxManagerType is assumed when a node's tx_manager.type is left
+// blank, so existing network YAMLs that only ever knew about Tessera keep
+// working unmodified.
+const defaultTxManagerType = "tessera"
+
+// TxManagerFactory builds the tx-manager Container for a single node. It is
+// handed the builder (for shared state such as the Docker client and
+// network) and the node's own config block.
+type TxManagerFactory func(ctx context.Context, qb *QuorumBuilder, idx int, node QuorumBuilderNode) (Container, error)
+
+var txManagerFactories = map[string]TxManagerFactory{}
+
+// RegisterTxManagerFactory makes a tx-manager backend selectable via the
+// `tx_manager.type` YAML discriminator. Backends register themselves from an
+// init() in their own file, mirroring how Tessera and Constellation do it.
+func RegisterTxManagerFactory(name string, factory TxManagerFactory) {
+	txManagerFactories[name] = factory
+}
+
+func init() {
+	RegisterTxManagerFactory(defaultTxManagerType, func(ctx context.Context, qb *QuorumBuilder, idx int, node QuorumBuilderNode) (Container, error) {
+		txManager, err := NewTesseraTxManager(
 			ConfigureNodeIndex(idx),
 			ConfigureProvisionId(qb.Name),
 			ConfigureDockerClient(qb.dockerClient),
@@ -114,15 +215,40 @@ func (qb *QuorumBuilder) startTxManagers() error {
 			ConfigureConfig(node.TxManager.Config),
 			ConfigureLabels(qb.commonLabels),
 		)
+		if err != nil {
+			return nil, err
+		}
+		return &tesseraReadinessContainer{
+			Container:  txManager,
+			upcheckURL: fmt.Sprintf("http://%s:9080/upcheck", nodeIP(idx, txManagerIPSlot)),
+		}, nil
 	})
 }
 
-func (qb *QuorumBuilder) startQuorums() error {
-	return qb.startContainers(func(idx int, node QuorumBuilderNode) (Container, error) {
-		if err := qb.pullImage(node.Quorum.Image); err != nil {
+func (qb *QuorumBuilder) startTxManagers(ctx context.Context) error {
+	log.Debug("Start Tx Managers")
+	return qb.startContainers(ctx, func(ctx context.Context, idx int, node QuorumBuilderNode) (Container, error) {
+		txManagerType := node.TxManager.Type
+		if txManagerType == "" {
+			txManagerType = defaultTxManagerType
+		}
+		factory, ok := txManagerFactories[txManagerType]
+		if !ok {
+			return nil, fmt.Errorf("tx manager %d: unknown type %q", idx, txManagerType)
+		}
+		if err := qb.pullImage(ctx, node.TxManager.Image); err != nil {
 			return nil, err
 		}
-		return NewQuorum(
+		return factory(ctx, qb, idx, node)
+	})
+}
+
+func (qb *QuorumBuilder) startQuorums(ctx context.Context) error {
+	return qb.startContainers(ctx, func(ctx context.Context, idx int, node QuorumBuilderNode) (Container, error) {
+		if err := qb.pullImage(ctx, node.Quorum.Image); err != nil {
+			return nil, err
+		}
+		quorum, err := NewQuorum(
 			ConfigureNodeIndex(idx),
 			ConfigureProvisionId(qb.Name),
 			ConfigureDockerClient(qb.dockerClient),
@@ -131,42 +257,68 @@ func (qb *QuorumBuilder) startQuorums() error {
 			ConfigureConfig(node.Quorum.Config),
 			ConfigureLabels(qb.commonLabels),
 		)
+		if err != nil {
+			return nil, err
+		}
+		minPeers := 0
+		if qb.requireFullMesh {
+			minPeers = len(qb.Nodes) - 1
+		}
+		rpcPort := node.Quorum.Config["rpc_port"]
+		if rpcPort == "" {
+			rpcPort = quorumRPCPort
+		}
+		return &quorumReadinessContainer{
+			Container: quorum,
+			rpcURL:    fmt.Sprintf("http://%s:%s", nodeIP(idx, quorumIPSlot), rpcPort),
+			minPeers:  minPeers,
+		}, nil
 	})
 }
 
-func (qb *QuorumBuilder) startContainers(containerFn func(idx int, node QuorumBuilderNode) (Container, error)) error {
-	readyChan := make(chan struct{})
-	errChan := make(chan error)
+// startContainers fans node construction+Start out over a worker pool bounded
+// by qb.concurrency(). The first error cancels the derived context so
+// in-flight pulls/starts abort quickly; every error seen before that point is
+// still collected and returned together via multierror.
+func (qb *QuorumBuilder) startContainers(ctx context.Context, containerFn func(ctx context.Context, idx int, node QuorumBuilderNode) (Container, error)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, qb.concurrency())
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs *multierror.Error
+
 	for idx, node := range qb.Nodes {
-		go func(_idx int, _node QuorumBuilderNode) {
-			c, err := containerFn(_idx, _node)
-			if err != nil {
-				errChan <- fmt.Errorf("container %d: %s", _idx, err)
+		wg.Add(1)
+		go func(idx int, node QuorumBuilderNode) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
 				return
 			}
-			log.Debug("Start Container", "idx", _idx)
-			if err := c.Start(); err != nil {
-				errChan <- fmt.Errorf("container %d: %s", _idx, err)
-			} else {
-				readyChan <- struct{}{}
+
+			c, err := containerFn(ctx, idx, node)
+			if err == nil {
+				log.Debug("Start Container", "idx", idx)
+				if err = c.Start(ctx); err == nil {
+					err = qb.waitReady(ctx, c)
+				}
+			}
+			if err != nil {
+				mu.Lock()
+				errs = multierror.Append(errs, fmt.Errorf("container %d: %s", idx, err))
+				mu.Unlock()
+				cancel()
 			}
 		}(idx, node)
 	}
-	readyCount := 0
-	allErr := make([]string, 0)
-	for {
-		select {
-		case <-readyChan:
-			readyCount++
-		case err := <-errChan:
-			allErr = append(allErr, err.Error())
-		}
-		if len(allErr)+readyCount >= len(qb.Nodes) {
-			break
-		}
-	}
-	if len(allErr) > 0 {
-		return fmt.Errorf("%d/%d containers are ready\n%s", readyCount, len(qb.Nodes), strings.Join(allErr, "\n"))
+	wg.Wait()
+
+	if errs != nil {
+		return errs.ErrorOrNil()
 	}
 	return nil
 }
@@ -181,51 +333,129 @@ func (qb *QuorumBuilder) buildDockerNetwork() error {
 	return nil
 }
 
-func (qb *QuorumBuilder) pullImage(image string) error {
+func (qb *QuorumBuilder) pullImage(ctx context.Context, image string) error {
 	log.Debug("Pull Docker Image", "name", image)
+	if qb.contentTrust && !isDigestPinned(image) {
+		if _, err := resolveNotarySignedDigest(image); err != nil {
+			return fmt.Errorf("pullImage: %s: content trust enabled and reference is not digest-pinned: %s", image, err)
+		}
+	}
+
 	filters := filters.NewArgs()
 	filters.Add("reference", image)
 
-	images, err := qb.dockerClient.ImageList(context.Background(), types.ImageListOptions{
+	images, err := qb.dockerClient.ImageList(ctx, types.ImageListOptions{
 		Filters: filters,
 	})
 
 	if len(images) == 0 || err != nil {
-		_, err := qb.dockerClient.ImagePull(context.Background(), image, types.ImagePullOptions{})
-		if err != nil {
+		if err := qb.streamImagePull(ctx, image); err != nil {
 			return fmt.Errorf("pullImage: %s - %s", image, err)
 		}
 	}
+
+	digest, err := qb.resolvePulledDigest(ctx, image)
+	if err != nil {
+		return fmt.Errorf("pullImage: %s - %s", image, err)
+	}
+	qb.recordImageDigest(image, digest)
+	return nil
+}
+
+// streamImagePull decodes the JSON progress stream ImagePull returns,
+// surfacing per-layer progress and aborting as soon as an error frame shows
+// up instead of silently discarding the response body.
+func (qb *QuorumBuilder) streamImagePull(ctx context.Context, image string) error {
+	rc, err := qb.dockerClient.ImagePull(ctx, image, types.ImagePullOptions{})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	decoder := json.NewDecoder(rc)
+	for {
+		var msg pullProgressMessage
+		if err := decoder.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if msg.Error != "" {
+			return fmt.Errorf("%s", msg.Error)
+		}
+		if msg.Progress != "" {
+			log.Debug("Pull progress", "image", image, "id", msg.ID, "status", msg.Status, "progress", msg.Progress)
+		} else {
+			log.Info("Pull progress", "image", image, "id", msg.ID, "status", msg.Status)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
 	return nil
 }
 
-func (qb *QuorumBuilder) Destroy() error {
+// resolvePulledDigest resolves image (a repo:tag or repo@sha256:... ref) to
+// the content digest of what was actually pulled. pullImage keeps it in
+// imageDigests, from where Inspect reports it; it is not added to
+// commonLabels, and Destroy does not check it.
+func (qb *QuorumBuilder) resolvePulledDigest(ctx context.Context, image string) (string, error) {
+	if digest, ok := parseDigestPin(image); ok {
+		return digest, nil
+	}
+	_, raw, err := qb.dockerClient.ImageInspectWithRaw(ctx, image)
+	if err != nil {
+		return "", err
+	}
+	var inspect struct {
+		RepoDigests []string `json:"RepoDigests"`
+	}
+	if err := json.Unmarshal(raw, &inspect); err != nil {
+		return "", err
+	}
+	repo := repoOf(image)
+	for _, repoDigest := range inspect.RepoDigests {
+		if strings.HasPrefix(repoDigest, repo+"@") {
+			return strings.TrimPrefix(repoDigest, repo+"@"), nil
+		}
+	}
+	if len(inspect.RepoDigests) > 0 {
+		repoDigest := inspect.RepoDigests[0]
+		if idx := strings.Index(repoDigest, "@"); idx != -1 {
+			return repoDigest[idx+1:], nil
+		}
+		return repoDigest, nil
+	}
+	return "", fmt.Errorf("no digest found for %s", image)
+}
+
+func (qb *QuorumBuilder) Destroy(ctx context.Context) error {
 	filters := filters.NewArgs()
 	for k, v := range qb.commonLabels {
 		filters.Add("label", fmt.Sprintf("%s=%s", k, v))
 	}
 	// find all containers
-	containers, err := qb.dockerClient.ContainerList(context.Background(), types.ContainerListOptions{Filters: filters})
+	containers, err := qb.dockerClient.ContainerList(ctx, types.ContainerListOptions{Filters: filters})
 	if err != nil {
 		return fmt.Errorf("destroy: %s", err)
 	}
-	if err := doWorkInParallel("removing containers", containersToGeneric(containers), func(el interface{}) error {
+	if err := qb.doWorkInParallel(ctx, "removing containers", containersToGeneric(containers), func(ctx context.Context, el interface{}) error {
 		c := el.(types.Container)
 		log.Debug("removing container", "id", c.ID[:6], "name", c.Names)
-		return qb.dockerClient.ContainerRemove(context.Background(), c.ID, types.ContainerRemoveOptions{Force: true})
+		return qb.dockerClient.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{Force: true})
 	}); err != nil {
 		return fmt.Errorf("destroy: %s", err)
 	}
 
 	// find networks
-	networks, err := qb.dockerClient.NetworkList(context.Background(), types.NetworkListOptions{Filters: filters})
+	networks, err := qb.dockerClient.NetworkList(ctx, types.NetworkListOptions{Filters: filters})
 	if err != nil {
 		return fmt.Errorf("destroy: %s", err)
 	}
-	if err := doWorkInParallel("removing network", networksToGeneric(networks), func(el interface{}) error {
+	if err := qb.doWorkInParallel(ctx, "removing network", networksToGeneric(networks), func(ctx context.Context, el interface{}) error {
 		c := el.(types.NetworkResource)
 		log.Debug("removing network", "id", c.ID[:6], "name", c.Name)
-		return qb.dockerClient.NetworkRemove(context.Background(), c.ID)
+		return qb.dockerClient.NetworkRemove(ctx, c.ID)
 	}); err != nil {
 		return fmt.Errorf("destroy: %s", err)
 	}
@@ -249,37 +479,47 @@ func networksToGeneric(n []types.NetworkResource) []interface{} {
 	return g
 }
 
-func doWorkInParallel(title string, elements []interface{}, callback func(el interface{}) error) error {
+// doWorkInParallel runs callback over elements through the same bounded
+// worker pool + cancel-on-first-error + multierror pattern as
+// startContainers, so a hung removal can't hold the whole Destroy open and a
+// panic'd sender can't deadlock it.
+func (qb *QuorumBuilder) doWorkInParallel(ctx context.Context, title string, elements []interface{}, callback func(ctx context.Context, el interface{}) error) error {
 	log.Debug(title)
 	if len(elements) == 0 {
 		return nil
 	}
-	doneChan := make(chan struct{})
-	errChan := make(chan error)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, qb.concurrency())
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs *multierror.Error
+
 	for _, el := range elements {
-		go func(_el interface{}) {
-			if err := callback(_el); err != nil {
-				errChan <- err
-			} else {
-				doneChan <- struct{}{}
+		wg.Add(1)
+		go func(el interface{}) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			if err := callback(ctx, el); err != nil {
+				mu.Lock()
+				errs = multierror.Append(errs, err)
+				mu.Unlock()
+				cancel()
 			}
 		}(el)
 	}
-	doneCount := 0
-	allErr := make([]string, 0)
-	for {
-		select {
-		case <-doneChan:
-			doneCount++
-		case err := <-errChan:
-			allErr = append(allErr, err.Error())
-		}
-		if len(allErr)+doneCount >= len(elements) {
-			break
-		}
-	}
-	if len(allErr) > 0 {
-		return fmt.Errorf("%s: %d/%d\n%s", title, doneCount, len(elements), strings.Join(allErr, "\n"))
+	wg.Wait()
+
+	if errs != nil {
+		return fmt.Errorf("%s: %s", title, errs.ErrorOrNil())
 	}
 	return nil
 }
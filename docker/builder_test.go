@@ -0,0 +1,161 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeContainer struct {
+	startErr error
+}
+
+func (f *fakeContainer) Start(ctx context.Context) error { return f.startErr }
+func (f *fakeContainer) Stop(ctx context.Context) error  { return nil }
+
+type fakeReadinessContainer struct {
+	fakeContainer
+	waitErr error
+	waited  int32
+}
+
+func (r *fakeReadinessContainer) WaitReady(ctx context.Context) error {
+	atomic.AddInt32(&r.waited, 1)
+	return r.waitErr
+}
+
+func TestStartContainersAggregatesStartErrors(t *testing.T) {
+	qb := &QuorumBuilder{Nodes: []QuorumBuilderNode{{}, {}, {}}}
+	qb.ConfigureConcurrency(4)
+
+	err := qb.startContainers(context.Background(), func(ctx context.Context, idx int, node QuorumBuilderNode) (Container, error) {
+		return &fakeContainer{startErr: fmt.Errorf("boom %d", idx)}, nil
+	})
+	if err == nil {
+		t.Fatal("expected an aggregated error when every container fails to start")
+	}
+}
+
+func TestStartContainersWaitsForReadiness(t *testing.T) {
+	qb := &QuorumBuilder{Nodes: []QuorumBuilderNode{{}}}
+	rc := &fakeReadinessContainer{waitErr: fmt.Errorf("not ready")}
+
+	err := qb.startContainers(context.Background(), func(ctx context.Context, idx int, node QuorumBuilderNode) (Container, error) {
+		return rc, nil
+	})
+	if err == nil {
+		t.Fatal("expected WaitReady's error to fail startContainers")
+	}
+	if got := atomic.LoadInt32(&rc.waited); got != 1 {
+		t.Errorf("WaitReady called %d times, want 1", got)
+	}
+}
+
+func TestStartTxManagersUnknownType(t *testing.T) {
+	qb := &QuorumBuilder{
+		Nodes: []QuorumBuilderNode{
+			{TxManager: QuorumBuilderNodeDocker{Type: "no-such-backend"}},
+		},
+	}
+	err := qb.startTxManagers(context.Background())
+	if err == nil {
+		t.Fatal("expected an error for an unregistered tx manager type")
+	}
+	if !strings.Contains(err.Error(), "no-such-backend") {
+		t.Errorf("error = %q, want it to mention the unknown type", err.Error())
+	}
+}
+
+func TestDoWorkInParallelEmptyIsNoop(t *testing.T) {
+	qb := &QuorumBuilder{}
+	called := false
+	err := qb.doWorkInParallel(context.Background(), "test", nil, func(ctx context.Context, el interface{}) error {
+		called = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("doWorkInParallel: %s", err)
+	}
+	if called {
+		t.Error("callback invoked for an empty element list")
+	}
+}
+
+func TestDoWorkInParallelRespectsConcurrencyBound(t *testing.T) {
+	qb := &QuorumBuilder{}
+	qb.ConfigureConcurrency(2)
+
+	elements := make([]interface{}, 8)
+	for i := range elements {
+		elements[i] = i
+	}
+
+	release := make(chan struct{})
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		close(release)
+	}()
+
+	var active, maxActive int32
+	err := qb.doWorkInParallel(context.Background(), "test", elements, func(ctx context.Context, el interface{}) error {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			old := atomic.LoadInt32(&maxActive)
+			if n <= old || atomic.CompareAndSwapInt32(&maxActive, old, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&active, -1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("doWorkInParallel: %s", err)
+	}
+	if maxActive > 2 {
+		t.Errorf("max concurrent callbacks = %d, want <= 2", maxActive)
+	}
+}
+
+func TestDoWorkInParallelAggregatesAndCancels(t *testing.T) {
+	qb := &QuorumBuilder{}
+	qb.ConfigureConcurrency(4)
+
+	elements := []interface{}{0, 1, 2, 3}
+	var started int32
+	err := qb.doWorkInParallel(context.Background(), "boom", elements, func(ctx context.Context, el interface{}) error {
+		atomic.AddInt32(&started, 1)
+		return fmt.Errorf("element %v failed", el)
+	})
+	if err == nil {
+		t.Fatal("expected an aggregated error")
+	}
+	if atomic.LoadInt32(&started) == 0 {
+		t.Fatal("expected at least one callback to run")
+	}
+	if !strings.Contains(err.Error(), "boom") {
+		t.Errorf("error = %q, want it to mention the work title %q", err.Error(), "boom")
+	}
+}
@@ -0,0 +1,215 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package docker
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/docker/docker/client"
+
+	"gopkg.in/yaml.v2"
+)
+
+// composeSubnet and composeGateway describe the user-defined network that
+// ExportCompose renders. They intentionally mirror the static addressing
+// buildDockerNetwork hands out, starting node IPs at .11 in index order, so
+// a round-tripped network keeps the same topology.
+const (
+	composeSubnet  = "172.25.239.0/24"
+	composeGateway = "172.25.239.1"
+)
+
+type composeFile struct {
+	Version  string                    `yaml:"version"`
+	Services map[string]composeService `yaml:"services"`
+	Networks map[string]composeNetwork `yaml:"networks"`
+	Volumes  map[string]composeVolume  `yaml:"volumes"`
+}
+
+type composeService struct {
+	Image         string                           `yaml:"image"`
+	ContainerName string                           `yaml:"container_name"`
+	// Type carries QuorumBuilderNodeDocker.Type (e.g. "constellation" for a
+	// tx manager) through the compose file so NewQuorumBuilderFromCompose can
+	// restore it rather than silently assuming defaultTxManagerType.
+	Type        string                           `yaml:"type,omitempty"`
+	Environment map[string]string                `yaml:"environment,omitempty"`
+	Volumes     []string                         `yaml:"volumes,omitempty"`
+	Networks    map[string]composeServiceNetwork `yaml:"networks"`
+}
+
+type composeServiceNetwork struct {
+	IPv4Address string `yaml:"ipv4_address"`
+}
+
+type composeNetwork struct {
+	Driver string      `yaml:"driver"`
+	IPAM   composeIPAM `yaml:"ipam"`
+}
+
+type composeIPAM struct {
+	Driver string              `yaml:"driver"`
+	Config []composeIPAMConfig `yaml:"config"`
+}
+
+type composeIPAMConfig struct {
+	Subnet string `yaml:"subnet"`
+}
+
+type composeVolume struct{}
+
+// nodeIPSlot identifies which of the two containers paired at a given node
+// index (the Quorum node itself, and its tx manager) a static IP is for.
+// They must never collide on the same docker network.
+type nodeIPSlot int
+
+const (
+	quorumIPSlot    nodeIPSlot = 0
+	txManagerIPSlot nodeIPSlot = 1
+)
+
+// nodeIPSlots is the number of distinct addresses reserved per node index.
+const nodeIPSlots = 2
+
+func nodeIP(idx int, slot nodeIPSlot) string {
+	return fmt.Sprintf("172.25.239.%d", 11+idx*nodeIPSlots+int(slot))
+}
+
+func quorumServiceName(name string, idx int) string {
+	return fmt.Sprintf("%s-quorum-%d", name, idx)
+}
+
+func txManagerServiceName(name string, idx int) string {
+	return fmt.Sprintf("%s-txmanager-%d", name, idx)
+}
+
+// ExportCompose renders the parsed network topology as a docker-compose v3
+// file: one service for each Quorum node and its paired tx manager, a shared
+// user-defined network with the static IPs buildDockerNetwork would assign,
+// and per-node volumes for genesis and keys. It does not require a Docker
+// daemon connection and can be used to hand the network off to CI runners or
+// compose-to-Swarm/Kubernetes converters such as kompose.
+func (qb *QuorumBuilder) ExportCompose(w io.Writer) error {
+	cf := composeFile{
+		Version:  "3",
+		Services: make(map[string]composeService, len(qb.Nodes)*2),
+		Networks: map[string]composeNetwork{
+			qb.Name: {
+				Driver: "bridge",
+				IPAM: composeIPAM{
+					Driver: "default",
+					Config: []composeIPAMConfig{{Subnet: composeSubnet}},
+				},
+			},
+		},
+		Volumes: make(map[string]composeVolume, len(qb.Nodes)*2),
+	}
+
+	for idx, node := range qb.Nodes {
+		genesisVol := fmt.Sprintf("%s-genesis-%d", qb.Name, idx)
+		keysVol := fmt.Sprintf("%s-keys-%d", qb.Name, idx)
+		cf.Volumes[genesisVol] = composeVolume{}
+		cf.Volumes[keysVol] = composeVolume{}
+
+		cf.Services[quorumServiceName(qb.Name, idx)] = composeService{
+			Image:         node.Quorum.Image,
+			ContainerName: quorumServiceName(qb.Name, idx),
+			Type:          node.Quorum.Type,
+			Environment:   node.Quorum.Config,
+			Volumes: []string{
+				fmt.Sprintf("%s:/quorum/genesis", genesisVol),
+				fmt.Sprintf("%s:/quorum/keys", keysVol),
+			},
+			Networks: map[string]composeServiceNetwork{
+				qb.Name: {IPv4Address: nodeIP(idx, quorumIPSlot)},
+			},
+		}
+
+		cf.Services[txManagerServiceName(qb.Name, idx)] = composeService{
+			Image:         node.TxManager.Image,
+			ContainerName: txManagerServiceName(qb.Name, idx),
+			Type:          node.TxManager.Type,
+			Environment:   node.TxManager.Config,
+			Volumes: []string{
+				fmt.Sprintf("%s:/quorum/keys", keysVol),
+			},
+			Networks: map[string]composeServiceNetwork{
+				qb.Name: {IPv4Address: nodeIP(idx, txManagerIPSlot)},
+			},
+		}
+	}
+
+	data, err := yaml.Marshal(cf)
+	if err != nil {
+		return fmt.Errorf("exportCompose: %s", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// NewQuorumBuilderFromCompose reconstructs a QuorumBuilder from a
+// docker-compose v3 file previously produced by ExportCompose, so a network
+// exported for Swarm/Kubernetes tooling can be round-tripped back into qctl.
+func NewQuorumBuilderFromCompose(r io.Reader, name string) (*QuorumBuilder, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	cf := composeFile{}
+	if err := yaml.Unmarshal(data, &cf); err != nil {
+		return nil, err
+	}
+
+	nodeCount := 0
+	for idx := 0; ; idx++ {
+		if _, ok := cf.Services[quorumServiceName(name, idx)]; !ok {
+			nodeCount = idx
+			break
+		}
+	}
+	if nodeCount == 0 {
+		return nil, fmt.Errorf("newQuorumBuilderFromCompose: no services found for network %q", name)
+	}
+
+	b := &QuorumBuilder{
+		Name:  name,
+		Nodes: make([]QuorumBuilderNode, nodeCount),
+	}
+	for idx := range b.Nodes {
+		quorumSvc := cf.Services[quorumServiceName(name, idx)]
+		txSvc := cf.Services[txManagerServiceName(name, idx)]
+		b.Nodes[idx] = QuorumBuilderNode{
+			Quorum:    QuorumBuilderNodeDocker{Type: quorumSvc.Type, Image: quorumSvc.Image, Config: quorumSvc.Environment},
+			TxManager: QuorumBuilderNodeDocker{Type: txSvc.Type, Image: txSvc.Image, Config: txSvc.Environment},
+		}
+	}
+
+	dockerClient, err := client.NewEnvClient()
+	if err != nil {
+		return nil, err
+	}
+	b.dockerClient = dockerClient
+	b.commonLabels = map[string]string{
+		"com.quorum.quorum-tools.id": b.Name,
+	}
+	return b, nil
+}
@@ -0,0 +1,127 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package docker
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNodeIPDistinctPerSlot(t *testing.T) {
+	quorumIP := nodeIP(0, quorumIPSlot)
+	txManagerIP := nodeIP(0, txManagerIPSlot)
+	if quorumIP == txManagerIP {
+		t.Fatalf("quorum and tx-manager share IP %q at the same node index", quorumIP)
+	}
+	if nodeIP(1, quorumIPSlot) == quorumIP {
+		t.Fatalf("node 0 and node 1 share IP %q", quorumIP)
+	}
+}
+
+func TestExportComposeRoundTrip(t *testing.T) {
+	qb := &QuorumBuilder{
+		Name: "testnet",
+		Nodes: []QuorumBuilderNode{
+			{
+				Quorum:    QuorumBuilderNodeDocker{Image: "quorumengineering/quorum:2.6.0", Config: map[string]string{"PRIVATE_CONFIG": "ignore"}},
+				TxManager: QuorumBuilderNodeDocker{Image: "quorumengineering/tessera:0.10.2", Config: map[string]string{"TOML_PATH": "/tm.toml"}},
+			},
+			{
+				Quorum:    QuorumBuilderNodeDocker{Image: "quorumengineering/quorum:2.6.0"},
+				TxManager: QuorumBuilderNodeDocker{Image: "quorumengineering/tessera:0.10.2"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := qb.ExportCompose(&buf); err != nil {
+		t.Fatalf("ExportCompose: %s", err)
+	}
+
+	got, err := NewQuorumBuilderFromCompose(&buf, "testnet")
+	if err != nil {
+		t.Fatalf("NewQuorumBuilderFromCompose: %s", err)
+	}
+
+	if got.Name != qb.Name {
+		t.Errorf("Name = %q, want %q", got.Name, qb.Name)
+	}
+	if len(got.Nodes) != len(qb.Nodes) {
+		t.Fatalf("got %d nodes, want %d", len(got.Nodes), len(qb.Nodes))
+	}
+	for i, node := range got.Nodes {
+		if node.Quorum.Image != qb.Nodes[i].Quorum.Image {
+			t.Errorf("node %d: Quorum.Image = %q, want %q", i, node.Quorum.Image, qb.Nodes[i].Quorum.Image)
+		}
+		if node.TxManager.Image != qb.Nodes[i].TxManager.Image {
+			t.Errorf("node %d: TxManager.Image = %q, want %q", i, node.TxManager.Image, qb.Nodes[i].TxManager.Image)
+		}
+	}
+}
+
+func TestExportComposeRoundTripPreservesType(t *testing.T) {
+	qb := &QuorumBuilder{
+		Name: "testnet",
+		Nodes: []QuorumBuilderNode{
+			{
+				Quorum:    QuorumBuilderNodeDocker{Image: "quorumengineering/quorum:2.6.0"},
+				TxManager: QuorumBuilderNodeDocker{Type: "constellation", Image: "quorumengineering/constellation:0.3.2"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := qb.ExportCompose(&buf); err != nil {
+		t.Fatalf("ExportCompose: %s", err)
+	}
+
+	got, err := NewQuorumBuilderFromCompose(&buf, "testnet")
+	if err != nil {
+		t.Fatalf("NewQuorumBuilderFromCompose: %s", err)
+	}
+
+	if got.Nodes[0].TxManager.Type != "constellation" {
+		t.Errorf("TxManager.Type = %q, want %q", got.Nodes[0].TxManager.Type, "constellation")
+	}
+	if got.Nodes[0].Quorum.Type != qb.Nodes[0].Quorum.Type {
+		t.Errorf("Quorum.Type = %q, want %q", got.Nodes[0].Quorum.Type, qb.Nodes[0].Quorum.Type)
+	}
+}
+
+func TestNewQuorumBuilderFromComposeUnknownName(t *testing.T) {
+	qb := &QuorumBuilder{
+		Name: "testnet",
+		Nodes: []QuorumBuilderNode{
+			{
+				Quorum:    QuorumBuilderNodeDocker{Image: "quorumengineering/quorum:2.6.0"},
+				TxManager: QuorumBuilderNodeDocker{Image: "quorumengineering/tessera:0.10.2"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := qb.ExportCompose(&buf); err != nil {
+		t.Fatalf("ExportCompose: %s", err)
+	}
+
+	if _, err := NewQuorumBuilderFromCompose(&buf, "othernet"); err == nil {
+		t.Fatal("expected an error importing a network name with no matching services, got nil")
+	}
+}
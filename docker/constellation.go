@@ -0,0 +1,172 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// constellationSocketPath is where the per-node ipc volume is mounted inside
+// the container, and constellationKeysPath is where the per-node
+// public/private keypair volume is mounted.
+const (
+	constellationSocketPath = "/constellation/ipc"
+	constellationKeysPath   = "/constellation/keys"
+)
+
+func init() {
+	RegisterTxManagerFactory("constellation", func(ctx context.Context, qb *QuorumBuilder, idx int, node QuorumBuilderNode) (Container, error) {
+		peers := make([]string, 0, len(qb.Nodes)-1)
+		for peerIdx := range qb.Nodes {
+			if peerIdx == idx {
+				continue
+			}
+			peers = append(peers, fmt.Sprintf("http://%s:9000/", nodeIP(peerIdx, txManagerIPSlot)))
+		}
+		return NewConstellationTxManager(ConstellationConfig{
+			NodeIndex:    idx,
+			ProvisionId:  qb.Name,
+			DockerClient: qb.dockerClient,
+			Network:      qb.dockerNetwork,
+			Image:        node.TxManager.Image,
+			Config:       node.TxManager.Config,
+			Labels:       qb.commonLabels,
+			Peers:        peers,
+		})
+	})
+}
+
+// ConstellationTxManager is a Container running the older Constellation
+// private tx manager, kept alongside TesseraTxManager for operators still
+// maintaining legacy Quorum 2.0 clusters.
+//
+// Constellation is started with --socket and --othernodes command-line flags,
+// and the node's config entries are passed as environment variables. The
+// per-node public/private keypair and the ipc path are both wired up via
+// named volumes at container-create time. The paired Quorum container does
+// not mount the ipc volume.
+type ConstellationTxManager struct {
+	ConstellationConfig
+
+	socketVolume string
+	containerID  string
+}
+
+// ConstellationConfig is the per-node wiring NewConstellationTxManager needs:
+// the docker plumbing shared with the rest of the builder, plus the
+// `--othernodes` peer list built from the docker network's assigned IPs.
+type ConstellationConfig struct {
+	NodeIndex    int
+	ProvisionId  string
+	DockerClient *client.Client
+	Network      *Network
+	Image        string
+	Config       map[string]string
+	Labels       map[string]string
+	Peers        []string
+}
+
+// NewConstellationTxManager wires up the legacy Constellation key/config
+// layout: public/private keys mounted per node, --socket pointed at a
+// per-node ipc volume (not mounted into the paired Quorum node), and
+// --othernodes set from cfg.Peers.
+func NewConstellationTxManager(cfg ConstellationConfig) (*ConstellationTxManager, error) {
+	c := &ConstellationTxManager{ConstellationConfig: cfg}
+	c.socketVolume = fmt.Sprintf("%s-constellation-ipc-%d", c.ProvisionId, c.NodeIndex)
+	return c, nil
+}
+
+func (c *ConstellationTxManager) containerName() string {
+	return txManagerServiceName(c.ProvisionId, c.NodeIndex)
+}
+
+// keysVolume is the per-node public/private keypair volume, named to match
+// the genesis/keys volumes ExportCompose renders so an exported and a live
+// network agree on where keys live.
+func (c *ConstellationTxManager) keysVolume() string {
+	return fmt.Sprintf("%s-keys-%d", c.ProvisionId, c.NodeIndex)
+}
+
+func (c *ConstellationTxManager) Start(ctx context.Context) error {
+	log.Debug("Start Constellation", "idx", c.NodeIndex, "othernodes", strings.Join(c.Peers, ","))
+
+	env := make([]string, 0, len(c.Config))
+	for k, v := range c.Config {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	resp, err := c.DockerClient.ContainerCreate(ctx,
+		&container.Config{
+			Image: c.Image,
+			Env:   env,
+			Cmd: []string{
+				"--socket=" + constellationSocketPath,
+				"--othernodes=" + strings.Join(c.Peers, ","),
+			},
+			Labels: c.Labels,
+		},
+		&container.HostConfig{
+			Binds: []string{
+				fmt.Sprintf("%s:%s", c.keysVolume(), constellationKeysPath),
+				fmt.Sprintf("%s:%s", c.socketVolume, constellationSocketPath),
+			},
+		},
+		&network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				c.Network.Name: {
+					IPAMConfig: &network.EndpointIPAMConfig{
+						IPv4Address: nodeIP(c.NodeIndex, txManagerIPSlot),
+					},
+				},
+			},
+		},
+		nil,
+		c.containerName(),
+	)
+	if err != nil {
+		return fmt.Errorf("start constellation %d: %s", c.NodeIndex, err)
+	}
+	c.containerID = resp.ID
+
+	if err := c.DockerClient.ContainerStart(ctx, c.containerID, types.ContainerStartOptions{}); err != nil {
+		return fmt.Errorf("start constellation %d: %s", c.NodeIndex, err)
+	}
+	return nil
+}
+
+func (c *ConstellationTxManager) Stop(ctx context.Context) error {
+	log.Debug("Stop Constellation", "idx", c.NodeIndex)
+	if c.containerID == "" {
+		return nil
+	}
+	if err := c.DockerClient.ContainerStop(ctx, c.containerID, nil); err != nil {
+		return fmt.Errorf("stop constellation %d: %s", c.NodeIndex, err)
+	}
+	return c.DockerClient.ContainerRemove(ctx, c.containerID, types.ContainerRemoveOptions{Force: true})
+}
@@ -0,0 +1,75 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package docker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pullProgressMessage is one line of the newline-delimited JSON progress
+// stream Docker's ImagePull returns.
+type pullProgressMessage struct {
+	Status   string `json:"status"`
+	ID       string `json:"id,omitempty"`
+	Progress string `json:"progress,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// isDigestPinned reports whether image is already a repo@sha256:... reference
+// rather than a mutable repo:tag.
+func isDigestPinned(image string) bool {
+	_, ok := parseDigestPin(image)
+	return ok
+}
+
+func parseDigestPin(image string) (string, bool) {
+	idx := strings.Index(image, "@sha256:")
+	if idx == -1 {
+		return "", false
+	}
+	return image[idx+1:], true
+}
+
+// repoOf strips the :tag or @digest suffix from an image reference.
+func repoOf(image string) string {
+	if idx := strings.Index(image, "@"); idx != -1 {
+		return image[:idx]
+	}
+	if idx := strings.LastIndex(image, ":"); idx != -1 && idx > strings.LastIndex(image, "/") {
+		return image[:idx]
+	}
+	return image
+}
+
+// digestLabelKey is the key under which the pinned digest for a given image
+// reference is recorded in QuorumBuilder.imageDigests. It is not written to
+// commonLabels or to any container label, and Destroy does not consult it.
+func digestLabelKey(image string) string {
+	return fmt.Sprintf("com.quorum.quorum-tools.digest.%s", repoOf(image))
+}
+
+// resolveNotarySignedDigest resolves a repo:tag reference to a notary-signed
+// digest when content trust is enabled. quorum-tools does not embed a notary
+// client, so a plain tag can never satisfy content trust today and the caller
+// must pin the digest explicitly in the network YAML.
+func resolveNotarySignedDigest(image string) (string, error) {
+	return "", fmt.Errorf("%s: no notary signer configured, pin the image by digest instead", image)
+}
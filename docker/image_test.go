@@ -0,0 +1,58 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package docker
+
+import "testing"
+
+func TestIsDigestPinned(t *testing.T) {
+	cases := map[string]bool{
+		"quorumengineering/quorum:2.6.0":                                         false,
+		"quorumengineering/quorum":                                               false,
+		"quorumengineering/quorum@sha256:abcd1234abcd1234abcd1234abcd1234abcd12": true,
+	}
+	for image, want := range cases {
+		if got := isDigestPinned(image); got != want {
+			t.Errorf("isDigestPinned(%q) = %v, want %v", image, got, want)
+		}
+	}
+}
+
+func TestRepoOf(t *testing.T) {
+	cases := map[string]string{
+		"quorumengineering/quorum:2.6.0":                                         "quorumengineering/quorum",
+		"quorumengineering/quorum":                                               "quorumengineering/quorum",
+		"quorumengineering/quorum@sha256:abcd1234abcd1234abcd1234abcd1234abcd12": "quorumengineering/quorum",
+		"localhost:5000/quorum:2.6.0":                                            "localhost:5000/quorum",
+		"localhost:5000/quorum":                                                  "localhost:5000/quorum",
+	}
+	for image, want := range cases {
+		if got := repoOf(image); got != want {
+			t.Errorf("repoOf(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
+
+func TestDigestLabelKey(t *testing.T) {
+	got := digestLabelKey("quorumengineering/quorum:2.6.0")
+	want := "com.quorum.quorum-tools.digest.quorumengineering/quorum"
+	if got != want {
+		t.Errorf("digestLabelKey(...) = %q, want %q", got, want)
+	}
+}
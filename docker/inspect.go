@@ -0,0 +1,247 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"gopkg.in/yaml.v2"
+)
+
+// NetworkInfo is the stable, machine-readable contract qctl quorum
+// inspect/up --export produce: everything a test harness or integration
+// suite needs to talk to a provisioned network without scraping logs.
+type NetworkInfo struct {
+	Name  string     `json:"name" yaml:"name"`
+	Nodes []NodeInfo `json:"nodes" yaml:"nodes"`
+}
+
+// NodeInfo describes one Quorum node and its paired tx manager.
+type NodeInfo struct {
+	Index       int            `json:"index" yaml:"index"`
+	ContainerID string         `json:"containerId" yaml:"containerId"`
+	IP          string         `json:"ip" yaml:"ip"`
+	RPCPort     string         `json:"rpcPort,omitempty" yaml:"rpcPort,omitempty"`
+	WSPort      string         `json:"wsPort,omitempty" yaml:"wsPort,omitempty"`
+	P2PPort     string         `json:"p2pPort,omitempty" yaml:"p2pPort,omitempty"`
+	EnodeURL    string         `json:"enodeUrl,omitempty" yaml:"enodeUrl,omitempty"`
+	ImageDigest string         `json:"imageDigest,omitempty" yaml:"imageDigest,omitempty"`
+	TxManager   *TxManagerInfo `json:"txManager,omitempty" yaml:"txManager,omitempty"`
+}
+
+// TxManagerInfo describes the private tx manager paired with a Quorum node.
+type TxManagerInfo struct {
+	PublicKey     string `json:"publicKey,omitempty" yaml:"publicKey,omitempty"`
+	ThirdPartyURL string `json:"thirdPartyUrl,omitempty" yaml:"thirdPartyUrl,omitempty"`
+	ImageDigest   string `json:"imageDigest,omitempty" yaml:"imageDigest,omitempty"`
+}
+
+// Inspect queries the running network by its provisioning label and
+// assembles a NetworkInfo document, enriched with the tx-manager public key
+// and the resolved image digests this builder pinned while pulling.
+func (qb *QuorumBuilder) Inspect(ctx context.Context) (*NetworkInfo, error) {
+	info, err := InspectByLabel(ctx, qb.dockerClient, qb.Name, func(idx int) string {
+		if idx >= len(qb.Nodes) {
+			return ""
+		}
+		return qb.Nodes[idx].Quorum.Config["rpc_port"]
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range info.Nodes {
+		node := info.Nodes[i]
+		if node.Index >= len(qb.Nodes) {
+			continue
+		}
+		configured := qb.Nodes[node.Index]
+		// Populated once startQuorums has actually pulled the Quorum image
+		// through pullImage; blank otherwise.
+		info.Nodes[i].ImageDigest = qb.imageDigest(configured.Quorum.Image)
+		if info.Nodes[i].TxManager != nil {
+			info.Nodes[i].TxManager.PublicKey = configured.TxManager.Config["public_key"]
+			info.Nodes[i].TxManager.ImageDigest = qb.imageDigest(configured.TxManager.Image)
+		}
+	}
+	return info, nil
+}
+
+// exportNetworkInfo backs `qctl quorum up --export`: it assembles this
+// builder's NetworkInfo and writes it as JSON (or YAML, if dest ends in
+// .yaml/.yml) to dest, or to stdout when dest is "-".
+func (qb *QuorumBuilder) exportNetworkInfo(ctx context.Context, dest string) error {
+	info, err := qb.Inspect(ctx)
+	if err != nil {
+		return fmt.Errorf("export: %s", err)
+	}
+
+	var data []byte
+	if strings.HasSuffix(dest, ".yaml") || strings.HasSuffix(dest, ".yml") {
+		data, err = yaml.Marshal(info)
+	} else {
+		data, err = json.MarshalIndent(info, "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("export: %s", err)
+	}
+
+	if dest == "-" {
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	return ioutil.WriteFile(dest, data, 0644)
+}
+
+// InspectByLabel queries a running network's containers by its
+// `com.quorum.quorum-tools.id` provisioning label alone, without needing the
+// original network YAML, so `qctl quorum inspect` can target a network
+// provisioned by a previous, separate `qctl quorum up` invocation.
+//
+// rpcPortFor, if non-nil, is consulted for each node index to resolve the
+// Quorum node's configured `rpc_port` override (see startQuorums); it may
+// return "" to fall back to quorumRPCPort. Callers with no access to the
+// original network YAML (the bare `qctl quorum inspect` CLI path) pass nil.
+func InspectByLabel(ctx context.Context, dockerClient *client.Client, provisionId string, rpcPortFor func(idx int) string) (*NetworkInfo, error) {
+	filterArgs := filters.NewArgs()
+	filterArgs.Add("label", fmt.Sprintf("com.quorum.quorum-tools.id=%s", provisionId))
+
+	containers, err := dockerClient.ContainerList(ctx, types.ContainerListOptions{Filters: filterArgs, All: true})
+	if err != nil {
+		return nil, fmt.Errorf("inspect: %s", err)
+	}
+
+	nodes := map[int]*NodeInfo{}
+	for _, c := range containers {
+		idx, role, ok := parseProvisionedContainerName(provisionId, containerName(c))
+		if !ok {
+			continue
+		}
+		node := nodes[idx]
+		if node == nil {
+			node = &NodeInfo{Index: idx, IP: nodeIP(idx, quorumIPSlot)}
+			nodes[idx] = node
+		}
+		switch role {
+		case "quorum":
+			node.ContainerID = c.ID
+			node.RPCPort, node.WSPort, node.P2PPort = hostPorts(c)
+			rpcPort := quorumRPCPort
+			if rpcPortFor != nil {
+				if p := rpcPortFor(idx); p != "" {
+					rpcPort = p
+				}
+			}
+			node.EnodeURL = enodeURL(ctx, node.IP, rpcPort)
+		case "txmanager":
+			node.TxManager = &TxManagerInfo{
+				ThirdPartyURL: fmt.Sprintf("http://%s:9080", nodeIP(idx, txManagerIPSlot)),
+			}
+		}
+	}
+
+	info := &NetworkInfo{Name: provisionId}
+	for idx := 0; idx < len(nodes); idx++ {
+		if node, ok := nodes[idx]; ok {
+			info.Nodes = append(info.Nodes, *node)
+		}
+	}
+	return info, nil
+}
+
+func containerName(c types.Container) string {
+	for _, name := range c.Names {
+		return strings.TrimPrefix(name, "/")
+	}
+	return ""
+}
+
+// parseProvisionedContainerName recovers the node index and role ("quorum" or
+// "txmanager") from a container name following the "<provisionId>-<role>-<idx>"
+// convention of quorumServiceName and txManagerServiceName.
+func parseProvisionedContainerName(provisionId, name string) (int, string, bool) {
+	prefix := provisionId + "-"
+	if !strings.HasPrefix(name, prefix) {
+		return 0, "", false
+	}
+	rest := strings.TrimPrefix(name, prefix)
+	sep := strings.LastIndex(rest, "-")
+	if sep == -1 {
+		return 0, "", false
+	}
+	role, idxStr := rest[:sep], rest[sep+1:]
+	idx := 0
+	if _, err := fmt.Sscanf(idxStr, "%d", &idx); err != nil {
+		return 0, "", false
+	}
+	return idx, role, true
+}
+
+func hostPorts(c types.Container) (rpcPort, wsPort, p2pPort string) {
+	for _, p := range c.Ports {
+		switch p.PrivatePort {
+		case 8545:
+			rpcPort = fmt.Sprintf("%d", p.PublicPort)
+		case 8546:
+			wsPort = fmt.Sprintf("%d", p.PublicPort)
+		case 30303:
+			p2pPort = fmt.Sprintf("%d", p.PublicPort)
+		}
+	}
+	return
+}
+
+// quorumRPCPort is the port Quorum's JSON-RPC listens on inside the
+// container, on the docker-network bridge, when a node doesn't override
+// rpc_port - distinct from whatever host port Docker happens to publish it
+// on (see hostPorts), which is only reachable from outside the bridge
+// network.
+const quorumRPCPort = "8545"
+
+// enodeURL queries the node's own JSON-RPC admin_nodeInfo for its enode URL,
+// dialing it on the docker-network bridge rather than through the
+// host-published port so it works whether or not the port was published.
+// Errors (the node not being reachable/ready yet) are swallowed - enode is
+// best-effort metadata, not required for Inspect to succeed.
+func enodeURL(ctx context.Context, ip, rpcPort string) string {
+	c, err := rpc.DialContext(ctx, fmt.Sprintf("http://%s:%s", ip, rpcPort))
+	if err != nil {
+		return ""
+	}
+	defer c.Close()
+
+	var nodeInfo struct {
+		Enode string `json:"enode"`
+	}
+	if err := c.CallContext(ctx, &nodeInfo, "admin_nodeInfo"); err != nil {
+		return ""
+	}
+	return nodeInfo.Enode
+}
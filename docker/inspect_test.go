@@ -0,0 +1,60 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestParseProvisionedContainerName(t *testing.T) {
+	idx, role, ok := parseProvisionedContainerName("testnet", "testnet-quorum-3")
+	if !ok || idx != 3 || role != "quorum" {
+		t.Fatalf("got (%d, %q, %v), want (3, \"quorum\", true)", idx, role, ok)
+	}
+
+	idx, role, ok = parseProvisionedContainerName("testnet", "testnet-txmanager-0")
+	if !ok || idx != 0 || role != "txmanager" {
+		t.Fatalf("got (%d, %q, %v), want (0, \"txmanager\", true)", idx, role, ok)
+	}
+
+	if _, _, ok := parseProvisionedContainerName("testnet", "othernet-quorum-0"); ok {
+		t.Fatalf("expected no match for a container outside the provisioning label's network")
+	}
+
+	if _, _, ok := parseProvisionedContainerName("testnet", "testnet-quorum"); ok {
+		t.Fatalf("expected no match for a name missing the trailing index")
+	}
+}
+
+func TestHostPorts(t *testing.T) {
+	c := types.Container{
+		Ports: []types.Port{
+			{PrivatePort: 8545, PublicPort: 32771},
+			{PrivatePort: 8546, PublicPort: 32772},
+			{PrivatePort: 30303, PublicPort: 32773},
+		},
+	}
+	rpcPort, wsPort, p2pPort := hostPorts(c)
+	if rpcPort != "32771" || wsPort != "32772" || p2pPort != "32773" {
+		t.Fatalf("got (%q, %q, %q), want (\"32771\", \"32772\", \"32773\")", rpcPort, wsPort, p2pPort)
+	}
+}
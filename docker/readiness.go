@@ -0,0 +1,163 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// defaultReadinessTimeout bounds how long startContainers waits for a single
+// node's WaitReady before giving up on it.
+const defaultReadinessTimeout = 60 * time.Second
+
+// Readiness is implemented by containers that need more than a successful
+// Start before downstream qctl commands can safely talk to them. Quorum's
+// RPC/IBFT peering and Tessera's P2P listener both come up asynchronously
+// after the process starts, so startContainers polls WaitReady instead of
+// trusting Start's return alone.
+type Readiness interface {
+	WaitReady(ctx context.Context) error
+}
+
+// ConfigureReadinessTimeout bounds how long startContainers waits on a single
+// node's WaitReady before treating it as failed to start. Defaults to
+// defaultReadinessTimeout.
+func (qb *QuorumBuilder) ConfigureReadinessTimeout(d time.Duration) {
+	qb.readinessTimeout = d
+}
+
+func (qb *QuorumBuilder) waitReadyTimeout() time.Duration {
+	if qb.readinessTimeout > 0 {
+		return qb.readinessTimeout
+	}
+	return defaultReadinessTimeout
+}
+
+// waitReady blocks on c.WaitReady, if c implements Readiness, bounded by
+// qb.waitReadyTimeout(). Containers that don't implement Readiness are
+// considered ready the moment Start returns, preserving today's behavior.
+func (qb *QuorumBuilder) waitReady(ctx context.Context, c Container) error {
+	r, ok := c.(Readiness)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, qb.waitReadyTimeout())
+	defer cancel()
+	return r.WaitReady(ctx)
+}
+
+// pollUpcheck polls a Tessera-style HTTP /upcheck endpoint until it returns
+// 200 OK or ctx is done.
+func pollUpcheck(ctx context.Context, url string) error {
+	client := &http.Client{Timeout: 2 * time.Second}
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s: %s", url, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollRPCPeerCount polls JSON-RPC net_version against rpcURL until it
+// succeeds, then (when minPeers > 0) waits for admin.peers to report at
+// least minPeers entries, matching IBFT's full-mesh convergence requirement.
+func pollRPCPeerCount(ctx context.Context, rpcURL string, minPeers int) error {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if ready, err := rpcIsReady(ctx, rpcURL, minPeers); err == nil && ready {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s: %s", rpcURL, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// tesseraReadinessContainer wraps the Container NewTesseraTxManager returns
+// so it also satisfies Readiness, polling Tessera's /upcheck endpoint on the
+// node's docker network IP.
+type tesseraReadinessContainer struct {
+	Container
+	upcheckURL string
+}
+
+func (t *tesseraReadinessContainer) WaitReady(ctx context.Context) error {
+	return pollUpcheck(ctx, t.upcheckURL)
+}
+
+// quorumReadinessContainer wraps the Container NewQuorum returns so it also
+// satisfies Readiness: JSON-RPC net_version must answer, and when minPeers
+// is set (--wait-peers), admin_peers must report full IBFT mesh convergence.
+type quorumReadinessContainer struct {
+	Container
+	rpcURL   string
+	minPeers int
+}
+
+func (q *quorumReadinessContainer) WaitReady(ctx context.Context) error {
+	return pollRPCPeerCount(ctx, q.rpcURL, q.minPeers)
+}
+
+func rpcIsReady(ctx context.Context, rpcURL string, minPeers int) (bool, error) {
+	c, err := rpc.DialContext(ctx, rpcURL)
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	var netVersion string
+	if err := c.CallContext(ctx, &netVersion, "net_version"); err != nil {
+		return false, err
+	}
+	if minPeers <= 0 {
+		return true, nil
+	}
+
+	var raw json.RawMessage
+	if err := c.CallContext(ctx, &raw, "admin_peers"); err != nil {
+		return false, err
+	}
+	var peers []json.RawMessage
+	if err := json.Unmarshal(raw, &peers); err != nil {
+		return false, err
+	}
+	log.Debug("admin_peers", "url", rpcURL, "count", len(peers), "want", minPeers)
+	return len(peers) >= minPeers, nil
+}
@@ -0,0 +1,141 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPollUpcheckSucceedsOn200(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := pollUpcheck(ctx, srv.URL); err != nil {
+		t.Fatalf("pollUpcheck: %s", err)
+	}
+}
+
+func TestPollUpcheckTimesOutWhileNotReady(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 700*time.Millisecond)
+	defer cancel()
+	if err := pollUpcheck(ctx, srv.URL); err == nil {
+		t.Fatal("expected pollUpcheck to time out against a server that never returns 200")
+	}
+}
+
+// jsonrpcRequest is the minimal shape pollRPCPeerCount's requests need
+// decoding into to route them to a method handler below.
+type jsonrpcRequest struct {
+	Method string          `json:"method"`
+	ID     json.RawMessage `json:"id"`
+}
+
+func newJSONRPCServer(t *testing.T, handlers map[string]func() (interface{}, error)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			// Can't call t.Fatalf from this handler goroutine; surface the
+			// failure as a JSON-RPC error instead so the client call returns
+			// an error the caller can assert on.
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"error":   map[string]interface{}{"code": -32700, "message": fmt.Sprintf("parse error: %s", err)},
+			})
+			return
+		}
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		handler, ok := handlers[req.Method]
+		switch {
+		case !ok:
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+		default:
+			result, err := handler()
+			if err != nil {
+				resp["error"] = map[string]interface{}{"code": -32000, "message": err.Error()}
+			} else {
+				resp["result"] = result
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestPollRPCPeerCountReturnsOnceRPCAnswers(t *testing.T) {
+	srv := newJSONRPCServer(t, map[string]func() (interface{}, error){
+		"net_version": func() (interface{}, error) { return "1337", nil },
+	})
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := pollRPCPeerCount(ctx, srv.URL, 0); err != nil {
+		t.Fatalf("pollRPCPeerCount: %s", err)
+	}
+}
+
+func TestPollRPCPeerCountWaitsForFullMesh(t *testing.T) {
+	var peerCalls int32
+	srv := newJSONRPCServer(t, map[string]func() (interface{}, error){
+		"net_version": func() (interface{}, error) { return "1337", nil },
+		"admin_peers": func() (interface{}, error) {
+			if atomic.AddInt32(&peerCalls, 1) < 2 {
+				return []interface{}{}, nil
+			}
+			return []interface{}{
+				map[string]interface{}{"id": "a"},
+				map[string]interface{}{"id": "b"},
+			}, nil
+		},
+	})
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := pollRPCPeerCount(ctx, srv.URL, 2); err != nil {
+		t.Fatalf("pollRPCPeerCount: %s", err)
+	}
+}
+
+func TestPollRPCPeerCountTimesOutWhenUnreachable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 700*time.Millisecond)
+	defer cancel()
+	if err := pollRPCPeerCount(ctx, "http://127.0.0.1:1", 0); err == nil {
+		t.Fatal("expected an error when the RPC endpoint is unreachable")
+	}
+}